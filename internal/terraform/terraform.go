@@ -0,0 +1,414 @@
+// Package terraform wraps github.com/hashicorp/terraform-exec so that osde2e-common callers get a consistently
+// configured terraform-exec runner without repeating setup boilerplate.
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	install "github.com/hashicorp/hc-install"
+	"github.com/hashicorp/hc-install/fs"
+	"github.com/hashicorp/hc-install/product"
+	"github.com/hashicorp/hc-install/releases"
+	"github.com/hashicorp/hc-install/src"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// openTofuReleaseBaseURL is OpenTofu's own release distribution point; it is not hosted by HashiCorp's releases
+// service, so it can't use releases.ExactVersion
+const openTofuReleaseBaseURL = "https://get.opentofu.org/tofu"
+
+// openTofuVersionOutput matches the leading "OpenTofu vX.Y.Z" line `tofu version` prints
+var openTofuVersionOutput = regexp.MustCompile(`OpenTofu v(\d+\.\d+\.\d+)`)
+
+// openTofuProduct mirrors product.Terraform so that hc-install's fs sources can find an installed OpenTofu
+// binary the same way they find Terraform; downloads go through openTofuExactVersion instead
+var openTofuProduct = product.Product{
+	Name: "tofu",
+	BinaryName: func() string {
+		if strings.HasSuffix(os.Args[0], ".exe") {
+			return "tofu.exe"
+		}
+		return "tofu"
+	},
+	GetVersion: func(ctx context.Context, path string) (*version.Version, error) {
+		out, err := exec.CommandContext(ctx, path, "version").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s version: %w", path, err)
+		}
+
+		matches := openTofuVersionOutput.FindSubmatch(out)
+		if len(matches) != 2 {
+			return nil, fmt.Errorf("unable to parse opentofu version from: %s", out)
+		}
+
+		return version.NewVersion(string(matches[1]))
+	},
+}
+
+// fallbackExactVersion is downloaded when no installed binary satisfies the requested constraint
+const fallbackExactVersion = "1.7.5"
+
+// openTofuExactVersion is a src.Source that downloads a single OpenTofu release archive from
+// get.opentofu.org rather than HashiCorp's release service, which does not host OpenTofu binaries
+type openTofuExactVersion struct {
+	Version *version.Version
+
+	installDir string
+}
+
+func (*openTofuExactVersion) IsSourceImpl() src.InstallSrcSigil {
+	return src.InstallSrcSigil{}
+}
+
+func (s *openTofuExactVersion) Validate() error {
+	if s.Version == nil {
+		return errors.New("opentofu version is required")
+	}
+	return nil
+}
+
+func (s *openTofuExactVersion) Install(ctx context.Context) (string, error) {
+	archiveURL := fmt.Sprintf("%s/%s/tofu_%s_%s_%s.zip", openTofuReleaseBaseURL, s.Version, s.Version, runtime.GOOS, runtime.GOARCH)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build opentofu download request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download opentofu from %s: %w", archiveURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download opentofu from %s: unexpected status %s", archiveURL, resp.Status)
+	}
+
+	archive, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read opentofu archive: %w", err)
+	}
+
+	installDir, err := os.MkdirTemp("", "opentofu")
+	if err != nil {
+		return "", fmt.Errorf("failed to create opentofu install directory: %w", err)
+	}
+	s.installDir = installDir
+
+	execPath, err := extractBinary(archive, openTofuProduct.BinaryName(), installDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract opentofu archive: %w", err)
+	}
+
+	return execPath, nil
+}
+
+func (s *openTofuExactVersion) Remove(ctx context.Context) error {
+	if s.installDir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.installDir)
+}
+
+// extractBinary extracts the file named binaryName out of the zip archive in data into destDir, marking it
+// executable, and returns its path
+func extractBinary(data []byte, binaryName, destDir string) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	for _, file := range reader.File {
+		if filepath.Base(file.Name) != binaryName {
+			continue
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to open %s in archive: %w", file.Name, err)
+		}
+		defer src.Close()
+
+		destPath := filepath.Join(destDir, binaryName)
+
+		dest, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+		if err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", destPath, err)
+		}
+		defer dest.Close()
+
+		if _, err := io.Copy(dest, src); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", destPath, err)
+		}
+
+		return destPath, nil
+	}
+
+	return "", fmt.Errorf("%s not found in archive", binaryName)
+}
+
+// fallbackSource returns the src.Source used to download fallbackExactVersion when no installed binary for engine
+// satisfies the requested constraint
+func fallbackSource(engine Engine) src.Source {
+	if engine == EngineOpenTofu {
+		return &openTofuExactVersion{Version: version.Must(version.NewVersion(fallbackExactVersion))}
+	}
+
+	return &releases.ExactVersion{
+		Product: product.Terraform,
+		Version: version.Must(version.NewVersion(fallbackExactVersion)),
+	}
+}
+
+// Engine selects which terraform-compatible CLI New installs and runs
+type Engine string
+
+const (
+	// EngineTerraform resolves and runs HashiCorp Terraform
+	EngineTerraform Engine = "terraform"
+
+	// EngineOpenTofu resolves and runs the OpenTofu fork. terraform-exec is wire-compatible with it, so the rest
+	// of Runner behaves identically regardless of which engine is selected.
+	EngineOpenTofu Engine = "opentofu"
+)
+
+// product returns the hc-install product used to resolve a binary for e
+func (e Engine) product() product.Product {
+	if e == EngineOpenTofu {
+		return openTofuProduct
+	}
+	return product.Terraform
+}
+
+// ErrWorkspaceExistsWithDifferentVariables is returned by CheckWorkspaceVariables when workingDir already holds an
+// initialized workspace whose terraform.tfvars disagree with the variables the caller is about to apply
+var ErrWorkspaceExistsWithDifferentVariables = errors.New("terraform workspace exists with different variables")
+
+// BackendConfig configures the terraform backend used to persist state across runs. When Type is empty, New writes
+// no backend block and terraform falls back to local state under workingDir.
+type BackendConfig struct {
+	// Type is the terraform backend type, e.g. "s3", "remote" or "http"
+	Type string
+
+	// Config holds the backend's HCL attributes (bucket, key, dynamodb_table, hostname, organization, address, ...)
+	Config map[string]string
+
+	// Workspace names the "remote" backend's workspace. It is rendered as a nested workspaces block and is only
+	// meaningful when Type is "remote"; other backend types have no such concept.
+	Workspace string
+}
+
+// Runner wraps a tfexec.Terraform runner with the conventions used across osde2e-common
+type Runner struct {
+	*tfexec.Terraform
+
+	// Engine is the engine this Runner was constructed for
+	Engine Engine
+
+	cleanup func()
+}
+
+// Install resolves a binary for engine satisfying constraint, preferring an already-installed binary on PATH and
+// falling back to downloading fallbackExactVersion into a temporary directory. The returned cleanup removes
+// anything that was downloaded and should be called once the binary is no longer needed.
+func Install(ctx context.Context, engine Engine, constraint string) (string, func(), error) {
+	versionConstraint, err := version.NewConstraint(constraint)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid %s version constraint %q: %w", engine, constraint, err)
+	}
+
+	installer := install.NewInstaller()
+	sources := []src.Source{
+		&fs.Version{
+			Product:     engine.product(),
+			Constraints: versionConstraint,
+		},
+		fallbackSource(engine),
+	}
+
+	execPath, err := installer.Ensure(ctx, sources)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to install a %s binary satisfying %q: %w", engine, constraint, err)
+	}
+
+	cleanup := func() { _ = installer.Remove(ctx) }
+
+	return execPath, cleanup, nil
+}
+
+// New constructs a Runner rooted at workingDir, installing a binary for engine (EngineTerraform when unset)
+// satisfying versionConstraint if one isn't already available. When backend is non-nil and its Type is set, its
+// configuration is rendered into workingDir as backend.tf before the caller invokes Init, so that Init picks up
+// the backend rather than defaulting to local state.
+func New(ctx context.Context, workingDir string, engine Engine, versionConstraint string, backend *BackendConfig) (*Runner, error) {
+	if engine == "" {
+		engine = EngineTerraform
+	}
+
+	execPath, cleanup, err := Install(ctx, engine, versionConstraint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install %s: %w", engine, err)
+	}
+
+	tf, err := tfexec.NewTerraform(workingDir, execPath)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to construct terraform runner: %w", err)
+	}
+
+	if err := writeBackendConfig(workingDir, backend); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to write terraform backend config: %w", err)
+	}
+
+	return &Runner{Terraform: tf, Engine: engine, cleanup: cleanup}, nil
+}
+
+// SetEnvVars sets the environment variables used for subsequent terraform invocations
+func (r *Runner) SetEnvVars(vars map[string]string) error {
+	return r.SetEnv(vars)
+}
+
+// Uninstall removes anything Install downloaded on behalf of this Runner
+func (r *Runner) Uninstall(ctx context.Context) error {
+	if r.cleanup != nil {
+		r.cleanup()
+	}
+	return nil
+}
+
+// UnmarshalOutput unmarshals the raw JSON value stored under key in output into out. It replaces the previous
+// pattern of stripping quotes from output[key].Value as a string, which corrupted any value containing a quote and
+// couldn't handle list or map outputs.
+func UnmarshalOutput(output map[string]tfexec.OutputMeta, key string, out any) error {
+	meta, ok := output[key]
+	if !ok {
+		return fmt.Errorf("terraform output %q not found", key)
+	}
+
+	if err := json.Unmarshal(meta.Value, out); err != nil {
+		return fmt.Errorf("failed to unmarshal terraform output %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// CheckWorkspaceVariables guards against two runs silently sharing a working directory. If workingDir has no
+// .terraform directory yet, or no terraform.tfvars, there's nothing to reconcile and it returns nil. Otherwise it
+// compares the stored variables against vars and returns ErrWorkspaceExistsWithDifferentVariables on any mismatch,
+// leaving it to the caller to Destroy the existing workspace or fail fast rather than reusing foreign state.
+func CheckWorkspaceVariables(workingDir string, vars map[string]string) error {
+	if _, err := os.Stat(filepath.Join(workingDir, ".terraform")); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat %s/.terraform: %w", workingDir, err)
+	}
+
+	tfvarsPath := filepath.Join(workingDir, "terraform.tfvars")
+
+	data, err := os.ReadFile(tfvarsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", tfvarsPath, err)
+	}
+
+	existing := parseTFVars(string(data))
+	for k, v := range vars {
+		if existing[k] != v {
+			return ErrWorkspaceExistsWithDifferentVariables
+		}
+	}
+
+	return nil
+}
+
+// WriteTFVars renders vars as workingDir/terraform.tfvars so a later CheckWorkspaceVariables call can detect drift
+func WriteTFVars(workingDir string, vars map[string]string) error {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s = %q\n", k, vars[k])
+	}
+
+	tfvarsPath := filepath.Join(workingDir, "terraform.tfvars")
+	if err := os.WriteFile(tfvarsPath, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("error writing %s: %w", tfvarsPath, err)
+	}
+
+	return nil
+}
+
+// parseTFVars does a best-effort parse of a flat "key = \"value\"" tfvars file, which is all WriteTFVars produces
+func parseTFVars(content string) map[string]string {
+	vars := make(map[string]string)
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+		vars[key] = value
+	}
+
+	return vars
+}
+
+// writeBackendConfig renders backend as an hcl backend block into workingDir/backend.tf. A nil backend, or one with
+// an empty Type, is treated as "use local state" and writes nothing.
+func writeBackendConfig(workingDir string, backend *BackendConfig) error {
+	if backend == nil || backend.Type == "" {
+		return nil
+	}
+
+	keys := make([]string, 0, len(backend.Config))
+	for k := range backend.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "terraform {\n  backend %q {\n", backend.Type)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "    %s = %q\n", k, backend.Config[k])
+	}
+	if backend.Type == "remote" && backend.Workspace != "" {
+		fmt.Fprintf(&b, "    workspaces {\n      name = %q\n    }\n", backend.Workspace)
+	}
+	b.WriteString("  }\n}\n")
+
+	if err := os.WriteFile(filepath.Join(workingDir, "backend.tf"), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("error writing %s/backend.tf: %w", workingDir, err)
+	}
+
+	return nil
+}
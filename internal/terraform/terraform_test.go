@@ -0,0 +1,165 @@
+package terraform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+func TestWriteTFVarsAndParseTFVars(t *testing.T) {
+	workingDir := t.TempDir()
+
+	vars := map[string]string{"aws_region": "us-east-1", "cluster_name": "my-cluster"}
+	if err := WriteTFVars(workingDir, vars); err != nil {
+		t.Fatalf("WriteTFVars() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workingDir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatalf("failed to read terraform.tfvars: %v", err)
+	}
+
+	got := parseTFVars(string(data))
+	if got["aws_region"] != "us-east-1" || got["cluster_name"] != "my-cluster" {
+		t.Fatalf("parseTFVars() = %v, want %v", got, vars)
+	}
+}
+
+func TestCheckWorkspaceVariables(t *testing.T) {
+	t.Run("no existing workspace", func(t *testing.T) {
+		workingDir := t.TempDir()
+
+		if err := CheckWorkspaceVariables(workingDir, map[string]string{"aws_region": "us-east-1"}); err != nil {
+			t.Fatalf("CheckWorkspaceVariables() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("matching variables", func(t *testing.T) {
+		workingDir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(workingDir, ".terraform"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		vars := map[string]string{"aws_region": "us-east-1", "cluster_name": "my-cluster"}
+		if err := WriteTFVars(workingDir, vars); err != nil {
+			t.Fatalf("WriteTFVars() error = %v", err)
+		}
+
+		if err := CheckWorkspaceVariables(workingDir, vars); err != nil {
+			t.Fatalf("CheckWorkspaceVariables() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("differing variables", func(t *testing.T) {
+		workingDir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(workingDir, ".terraform"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := WriteTFVars(workingDir, map[string]string{"aws_region": "us-east-1", "cluster_name": "my-cluster"}); err != nil {
+			t.Fatalf("WriteTFVars() error = %v", err)
+		}
+
+		err := CheckWorkspaceVariables(workingDir, map[string]string{"aws_region": "us-west-2", "cluster_name": "my-cluster"})
+		if !errors.Is(err, ErrWorkspaceExistsWithDifferentVariables) {
+			t.Fatalf("CheckWorkspaceVariables() error = %v, want ErrWorkspaceExistsWithDifferentVariables", err)
+		}
+	})
+}
+
+func TestUnmarshalOutput(t *testing.T) {
+	output := map[string]tfexec.OutputMeta{
+		"cluster-private-subnet": {Value: json.RawMessage(`"subnet-abc123"`)},
+		"node-private-subnets":   {Value: json.RawMessage(`["subnet-1","subnet-2"]`)},
+	}
+
+	var subnet string
+	if err := UnmarshalOutput(output, "cluster-private-subnet", &subnet); err != nil {
+		t.Fatalf("UnmarshalOutput() error = %v", err)
+	}
+	if subnet != "subnet-abc123" {
+		t.Fatalf("UnmarshalOutput() subnet = %q, want %q", subnet, "subnet-abc123")
+	}
+
+	var subnets []string
+	if err := UnmarshalOutput(output, "node-private-subnets", &subnets); err != nil {
+		t.Fatalf("UnmarshalOutput() error = %v", err)
+	}
+	if len(subnets) != 2 || subnets[0] != "subnet-1" || subnets[1] != "subnet-2" {
+		t.Fatalf("UnmarshalOutput() subnets = %v, want [subnet-1 subnet-2]", subnets)
+	}
+
+	if err := UnmarshalOutput(output, "missing-output", &subnet); err == nil {
+		t.Fatal("UnmarshalOutput() error = nil, want error for missing output key")
+	}
+}
+
+func TestWriteBackendConfig(t *testing.T) {
+	t.Run("remote renders a nested workspaces block", func(t *testing.T) {
+		workingDir := t.TempDir()
+		backend := &BackendConfig{Type: "remote", Config: map[string]string{"hostname": "tfe.example.com"}, Workspace: "my-cluster"}
+
+		if err := writeBackendConfig(workingDir, backend); err != nil {
+			t.Fatalf("writeBackendConfig() error = %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(workingDir, "backend.tf"))
+		if err != nil {
+			t.Fatalf("failed to read backend.tf: %v", err)
+		}
+
+		got := string(data)
+		if !strings.Contains(got, "workspaces {\n      name = \"my-cluster\"\n    }") {
+			t.Fatalf("backend.tf = %s, want a nested workspaces block", got)
+		}
+		if strings.Contains(got, "workspace = ") {
+			t.Fatalf("backend.tf = %s, want no flat workspace attribute", got)
+		}
+	})
+
+	t.Run("s3 and http render flat attribute lists", func(t *testing.T) {
+		for _, backend := range []*BackendConfig{
+			{Type: "s3", Config: map[string]string{"bucket": "my-bucket", "key": "hcp-vpc/my-cluster/terraform.tfstate"}},
+			{Type: "http", Config: map[string]string{"address": "https://example.com/state/my-cluster"}},
+		} {
+			workingDir := t.TempDir()
+
+			if err := writeBackendConfig(workingDir, backend); err != nil {
+				t.Fatalf("writeBackendConfig() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(workingDir, "backend.tf"))
+			if err != nil {
+				t.Fatalf("failed to read backend.tf: %v", err)
+			}
+
+			got := string(data)
+			for k, v := range backend.Config {
+				if want := fmt.Sprintf("%s = %q", k, v); !strings.Contains(got, want) {
+					t.Fatalf("backend.tf = %s, want to contain %q", got, want)
+				}
+			}
+			if strings.Contains(got, "workspaces {") {
+				t.Fatalf("backend.tf = %s, want no workspaces block for %s", got, backend.Type)
+			}
+		}
+	})
+}
+
+func TestEngineProduct(t *testing.T) {
+	if got := string(EngineTerraform.product().Name); got != "terraform" {
+		t.Fatalf("EngineTerraform.product().Name = %q, want %q", got, "terraform")
+	}
+	if got := string(EngineOpenTofu.product().Name); got != "tofu" {
+		t.Fatalf("EngineOpenTofu.product().Name = %q, want %q", got, "tofu")
+	}
+	if got := string(Engine("").product().Name); got != "terraform" {
+		t.Fatalf("Engine(\"\").product().Name = %q, want %q", got, "terraform")
+	}
+}
@@ -15,9 +15,9 @@ import (
 
 // vpc represents the details of an aws vpc
 type vpc struct {
-	privateSubnet     string
-	publicSubnet      string
-	nodePrivateSubnet string
+	privateSubnet      string
+	publicSubnet       string
+	nodePrivateSubnets []string
 }
 
 // hpcVPCError represents the custom error
@@ -26,6 +26,18 @@ type hcpVPCError struct {
 	err    error
 }
 
+// HCPVPCModule overrides the terraform module used to provision the hosted control plane vpc
+type HCPVPCModule struct {
+	// Inline is HCL written directly into the working directory as setup-hcp-vpc.tf
+	Inline string
+
+	// Remote is any terraform module source address (git repo, S3, local path or Terraform Registry module
+	// address). It is called as a child module from a generated setup-hcp-vpc.tf wrapper, which passes it
+	// aws_region and cluster_name and re-exports its cluster-private-subnet, cluster-public-subnet and
+	// node-private-subnets outputs
+	Remote string
+}
+
 // Error returns the formatted error message when hpcVPCError is invoked
 func (h *hcpVPCError) Error() string {
 	return fmt.Sprintf("%s hcp cluster vpc failed: %v", h.action, h.err)
@@ -53,6 +65,96 @@ func copyFile(srcFile, destFile string) error {
 	return nil
 }
 
+// writeHCPVPCModule populates workingDir with the terraform module used to provision the hosted control plane vpc
+func writeHCPVPCModule(module *HCPVPCModule, workingDir string) error {
+	destFile := fmt.Sprintf("%s/setup-hcp-vpc.tf", workingDir)
+
+	switch {
+	case module == nil:
+		return copyFile("assets/setup-hcp-vpc.tf", destFile)
+	case module.Inline != "":
+		if err := os.WriteFile(destFile, []byte(module.Inline), 0o644); err != nil {
+			return fmt.Errorf("error writing inline terraform module to destination file: %w", err)
+		}
+		return nil
+	case module.Remote != "":
+		wrapper := fmt.Sprintf(`variable "aws_region" {
+  type = string
+}
+
+variable "cluster_name" {
+  type = string
+}
+
+module "hcp_vpc" {
+  source       = %q
+  aws_region   = var.aws_region
+  cluster_name = var.cluster_name
+}
+
+output "cluster-private-subnet" {
+  value = module.hcp_vpc.cluster-private-subnet
+}
+
+output "cluster-public-subnet" {
+  value = module.hcp_vpc.cluster-public-subnet
+}
+
+output "node-private-subnets" {
+  value = module.hcp_vpc.node-private-subnets
+}
+`, module.Remote)
+
+		if err := os.WriteFile(destFile, []byte(wrapper), 0o644); err != nil {
+			return fmt.Errorf("error writing remote terraform module wrapper to destination file: %w", err)
+		}
+		return nil
+	default:
+		return copyFile("assets/setup-hcp-vpc.tf", destFile)
+	}
+}
+
+// terraformEngineLoggerKey is the logger key used to record the terraform-compatible engine in use
+const terraformEngineLoggerKey = "terraform_engine"
+
+// defaultTerraformVersionConstraint is used when Provider.TerraformVersionConstraint is unset
+const defaultTerraformVersionConstraint = ">= 1.2.0"
+
+// terraformVersionConstraint returns r's configured terraform version constraint, or the default when unset
+func terraformVersionConstraint(r *Provider) string {
+	if r.TerraformVersionConstraint != "" {
+		return r.TerraformVersionConstraint
+	}
+	return defaultTerraformVersionConstraint
+}
+
+// backendConfigForCluster derives base's per-cluster backend configuration for clusterName
+func backendConfigForCluster(base *terraform.BackendConfig, clusterName string) *terraform.BackendConfig {
+	if base == nil {
+		return nil
+	}
+
+	config := make(map[string]string, len(base.Config)+1)
+	for k, v := range base.Config {
+		config[k] = v
+	}
+
+	workspace := base.Workspace
+
+	switch base.Type {
+	case "s3":
+		config["key"] = fmt.Sprintf("hcp-vpc/%s/terraform.tfstate", clusterName)
+	case "remote":
+		workspace = clusterName
+	case "http":
+		if address := config["address"]; address != "" {
+			config["address"] = fmt.Sprintf("%s/%s", strings.TrimRight(address, "/"), clusterName)
+		}
+	}
+
+	return &terraform.BackendConfig{Type: base.Type, Config: config, Workspace: workspace}
+}
+
 // createHostedControlPlaneVPC creates the aws vpc used for provisioning hosted control plane clusters
 func (r *Provider) createHostedControlPlaneVPC(ctx context.Context, clusterName, awsRegion, workingDir string) (*vpc, error) {
 	action := "create"
@@ -62,12 +164,17 @@ func (r *Provider) createHostedControlPlaneVPC(ctx context.Context, clusterName,
 		return nil, &hcpVPCError{action: action, err: errors.New("one or more parameters is empty")}
 	}
 
-	tf, err := terraform.New(ctx, workingDir)
+	tf, err := terraform.New(ctx, workingDir, r.Engine, terraformVersionConstraint(r), backendConfigForCluster(r.BackendConfig, clusterName))
 	if err != nil {
 		return nil, &hcpVPCError{action: action, err: fmt.Errorf("failed to construct terraform runner: %v", err)}
 	}
 
-	if err = tf.SetEnvVars(r.awsCredentials.CredentialsAsMap()); err != nil {
+	envVars, _, err := r.terraformCredentials(ctx)
+	if err != nil {
+		return nil, &hcpVPCError{action: action, err: err}
+	}
+
+	if err = tf.SetEnvVars(envVars); err != nil {
 		return nil, &hcpVPCError{action: action, err: fmt.Errorf("failed to set terraform runner aws credentials (env vars): %v", err)}
 	}
 
@@ -75,11 +182,21 @@ func (r *Provider) createHostedControlPlaneVPC(ctx context.Context, clusterName,
 		_ = tf.Uninstall(ctx)
 	}()
 
-	r.log.Info("Creating aws vpc", clusterNameLoggerKey, clusterName, awsRegionLoggerKey, awsRegion)
+	r.log.Info("Creating aws vpc", clusterNameLoggerKey, clusterName, awsRegionLoggerKey, awsRegion, terraformEngineLoggerKey, tf.Engine)
+
+	vars := map[string]string{"aws_region": awsRegion, "cluster_name": clusterName}
+
+	if err = terraform.CheckWorkspaceVariables(workingDir, vars); err != nil {
+		return nil, &hcpVPCError{action: action, err: err}
+	}
 
-	err = copyFile("assets/setup-hcp-vpc.tf", fmt.Sprintf("%s/setup-hcp-vpc.tf", workingDir))
+	err = writeHCPVPCModule(r.HCPVPCModule, workingDir)
 	if err != nil {
-		return nil, &hcpVPCError{action: action, err: fmt.Errorf("failed to copy terraform file to working directory: %v", err)}
+		return nil, &hcpVPCError{action: action, err: fmt.Errorf("failed to write terraform module to working directory: %v", err)}
+	}
+
+	if err = terraform.WriteTFVars(workingDir, vars); err != nil {
+		return nil, &hcpVPCError{action: action, err: fmt.Errorf("failed to write terraform variables: %v", err)}
 	}
 
 	err = tf.Init(ctx)
@@ -106,9 +223,15 @@ func (r *Provider) createHostedControlPlaneVPC(ctx context.Context, clusterName,
 		return nil, &hcpVPCError{action: action, err: fmt.Errorf("failed to perform terraform output: %v", err)}
 	}
 
-	vpc.privateSubnet = strings.ReplaceAll(string(output["cluster-private-subnet"].Value), "\"", "")
-	vpc.publicSubnet = strings.ReplaceAll(string(output["cluster-public-subnet"].Value), "\"", "")
-	vpc.nodePrivateSubnet = strings.ReplaceAll(string(output["node-private-subnet"].Value), "\"", "")
+	if err := terraform.UnmarshalOutput(output, "cluster-private-subnet", &vpc.privateSubnet); err != nil {
+		return nil, &hcpVPCError{action: action, err: err}
+	}
+	if err := terraform.UnmarshalOutput(output, "cluster-public-subnet", &vpc.publicSubnet); err != nil {
+		return nil, &hcpVPCError{action: action, err: err}
+	}
+	if err := terraform.UnmarshalOutput(output, "node-private-subnets", &vpc.nodePrivateSubnets); err != nil {
+		return nil, &hcpVPCError{action: action, err: err}
+	}
 
 	r.log.Info("AWS vpc created!", clusterNameLoggerKey, clusterName, terraformWorkingDirLoggerKey, workingDir)
 
@@ -123,12 +246,17 @@ func (r *Provider) deleteHostedControlPlaneVPC(ctx context.Context, clusterName,
 		return &hcpVPCError{action: action, err: errors.New("one or more parameters is empty")}
 	}
 
-	tf, err := terraform.New(ctx, workingDir)
+	tf, err := terraform.New(ctx, workingDir, r.Engine, terraformVersionConstraint(r), backendConfigForCluster(r.BackendConfig, clusterName))
 	if err != nil {
 		return &hcpVPCError{action: action, err: fmt.Errorf("failed to construct terraform runner: %v", err)}
 	}
 
-	if err = tf.SetEnvVars(r.awsCredentials.CredentialsAsMap()); err != nil {
+	envVars, roleCreds, err := r.terraformCredentials(ctx)
+	if err != nil {
+		return &hcpVPCError{action: action, err: err}
+	}
+
+	if err = tf.SetEnvVars(envVars); err != nil {
 		return &hcpVPCError{action: action, err: fmt.Errorf("failed to set terraform runner aws credentials (env vars): %v", err)}
 	}
 
@@ -136,13 +264,24 @@ func (r *Provider) deleteHostedControlPlaneVPC(ctx context.Context, clusterName,
 		_ = tf.Uninstall(ctx)
 	}()
 
-	r.log.Info("Deleting aws vpc", clusterNameLoggerKey, clusterName, awsRegionLoggerKey, awsRegion, terraformWorkingDirLoggerKey, workingDir)
+	r.log.Info("Deleting aws vpc", clusterNameLoggerKey, clusterName, awsRegionLoggerKey, awsRegion, terraformWorkingDirLoggerKey, workingDir, terraformEngineLoggerKey, tf.Engine)
 
 	err = tf.Init(ctx)
 	if err != nil {
 		return &hcpVPCError{action: action, err: fmt.Errorf("failed to perform terraform init: %v", err)}
 	}
 
+	if roleCreds != nil && roleCreds.expiresWithin(roleCredentialRefreshWindow) {
+		roleCreds, err = assumeRole(ctx, *r.AssumeRoleConfig)
+		if err != nil {
+			return &hcpVPCError{action: action, err: err}
+		}
+
+		if err = tf.SetEnvVars(roleCreds.asMap()); err != nil {
+			return &hcpVPCError{action: action, err: fmt.Errorf("failed to refresh terraform runner aws credentials (env vars): %v", err)}
+		}
+	}
+
 	err = tf.Destroy(
 		ctx,
 		tfexec.Var(fmt.Sprintf("aws_region=%s", awsRegion)),
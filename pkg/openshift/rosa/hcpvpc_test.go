@@ -0,0 +1,56 @@
+package rosa
+
+import (
+	"testing"
+
+	"github.com/openshift/osde2e-common/internal/terraform"
+)
+
+func TestBackendConfigForCluster(t *testing.T) {
+	t.Run("nil base", func(t *testing.T) {
+		if got := backendConfigForCluster(nil, "my-cluster"); got != nil {
+			t.Fatalf("backendConfigForCluster() = %v, want nil", got)
+		}
+	})
+
+	t.Run("s3 derives a per-cluster key", func(t *testing.T) {
+		base := &terraform.BackendConfig{Type: "s3", Config: map[string]string{"bucket": "my-bucket"}}
+
+		got := backendConfigForCluster(base, "my-cluster")
+
+		want := "hcp-vpc/my-cluster/terraform.tfstate"
+		if got.Config["key"] != want {
+			t.Fatalf("Config[key] = %q, want %q", got.Config["key"], want)
+		}
+		if got.Config["bucket"] != "my-bucket" {
+			t.Fatalf("Config[bucket] = %q, want %q", got.Config["bucket"], "my-bucket")
+		}
+	})
+
+	t.Run("remote derives a per-cluster workspace", func(t *testing.T) {
+		base := &terraform.BackendConfig{Type: "remote", Config: map[string]string{"hostname": "tfe.example.com"}}
+
+		got := backendConfigForCluster(base, "my-cluster")
+
+		if got.Workspace != "my-cluster" {
+			t.Fatalf("Workspace = %q, want %q", got.Workspace, "my-cluster")
+		}
+		if _, ok := got.Config["workspace"]; ok {
+			t.Fatal("Config[workspace] should not be set; remote workspaces are a nested block, not a flat attribute")
+		}
+	})
+
+	t.Run("http partitions via the address path instead of a workspace attribute", func(t *testing.T) {
+		base := &terraform.BackendConfig{Type: "http", Config: map[string]string{"address": "https://example.com/state"}}
+
+		got := backendConfigForCluster(base, "my-cluster")
+
+		want := "https://example.com/state/my-cluster"
+		if got.Config["address"] != want {
+			t.Fatalf("Config[address] = %q, want %q", got.Config["address"], want)
+		}
+		if _, ok := got.Config["workspace"]; ok {
+			t.Fatal("Config[workspace] should not be set; the http backend has no workspace concept")
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package rosa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// roleCredentialRefreshWindow is how far ahead of expiration assumed role credentials are refreshed
+const roleCredentialRefreshWindow = 5 * time.Minute
+
+// AssumeRoleConfig configures the AWS STS role terraform should assume for HCP VPC operations
+type AssumeRoleConfig struct {
+	RoleARN         string
+	SessionName     string
+	ExternalID      string
+	DurationSeconds int32
+}
+
+// assumedCredentials holds the temporary credentials returned by sts.AssumeRole and their expiration
+type assumedCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	expiration      time.Time
+}
+
+// expiresWithin reports whether c expires before d from now
+func (c assumedCredentials) expiresWithin(d time.Duration) bool {
+	return time.Now().Add(d).After(c.expiration)
+}
+
+// asMap renders c as terraform process environment variables
+func (c assumedCredentials) asMap() map[string]string {
+	return map[string]string{
+		"AWS_ACCESS_KEY_ID":     c.accessKeyID,
+		"AWS_SECRET_ACCESS_KEY": c.secretAccessKey,
+		"AWS_SESSION_TOKEN":     c.sessionToken,
+	}
+}
+
+// assumeRole calls sts.AssumeRole using roleConfig
+func assumeRole(ctx context.Context, roleConfig AssumeRoleConfig) (*assumedCredentials, error) {
+	awsConfig, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	client := sts.NewFromConfig(awsConfig)
+
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleConfig.RoleARN),
+		RoleSessionName: aws.String(roleConfig.SessionName),
+	}
+	if roleConfig.ExternalID != "" {
+		input.ExternalId = aws.String(roleConfig.ExternalID)
+	}
+	if roleConfig.DurationSeconds > 0 {
+		input.DurationSeconds = aws.Int32(roleConfig.DurationSeconds)
+	}
+
+	output, err := client.AssumeRole(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %w", roleConfig.RoleARN, err)
+	}
+
+	return &assumedCredentials{
+		accessKeyID:     aws.ToString(output.Credentials.AccessKeyId),
+		secretAccessKey: aws.ToString(output.Credentials.SecretAccessKey),
+		sessionToken:    aws.ToString(output.Credentials.SessionToken),
+		expiration:      aws.ToTime(output.Credentials.Expiration),
+	}, nil
+}
+
+// terraformCredentials returns the environment variables terraform should use for r, assuming
+// r.AssumeRoleConfig's role when set and falling back to r.awsCredentials otherwise
+func (r *Provider) terraformCredentials(ctx context.Context) (map[string]string, *assumedCredentials, error) {
+	if r.AssumeRoleConfig == nil {
+		return r.awsCredentials.CredentialsAsMap(), nil, nil
+	}
+
+	creds, err := assumeRole(ctx, *r.AssumeRoleConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return creds.asMap(), creds, nil
+}
@@ -0,0 +1,28 @@
+package rosa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssumedCredentialsExpiresWithin(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration time.Time
+		window     time.Duration
+		want       bool
+	}{
+		{name: "well within validity", expiration: time.Now().Add(time.Hour), window: roleCredentialRefreshWindow, want: false},
+		{name: "inside the refresh window", expiration: time.Now().Add(time.Minute), window: roleCredentialRefreshWindow, want: true},
+		{name: "already expired", expiration: time.Now().Add(-time.Minute), window: roleCredentialRefreshWindow, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds := assumedCredentials{expiration: tt.expiration}
+			if got := creds.expiresWithin(tt.window); got != tt.want {
+				t.Fatalf("expiresWithin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}